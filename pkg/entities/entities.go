@@ -0,0 +1,70 @@
+// Package entities holds the types and interfaces shared across storage
+// backends (pkg/services/postgres, pkg/sqlite, pkg/memstore) so callers can
+// depend on an abstraction instead of a concrete driver.
+package entities
+
+import "time"
+
+// TwitterProfile represents a user's profile on Twitter, independent of
+// which storage backend it was loaded from.
+type TwitterProfile struct {
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	TwitterID           string
+	Name                string
+	Username            string
+	Location            string
+	Bio                 string
+	URL                 string
+	Email               string
+	ProfileBannerURL    string
+	ProfileImageURL     string
+	Verified            bool
+	Protected           bool
+	DefaultProfile      bool
+	DefaultProfileImage bool
+	FollowersCount      int
+	FollowingsCount     int
+	FavouritesCount     int
+	ListedCount         int
+	TweetsCount         int
+	Entities            map[string]interface{}
+	JoinedAt            time.Time
+}
+
+// ConfigGetter is the subset of *viper.Viper a storage backend needs to
+// read its configuration, kept as an interface so backends stay testable
+// without a real viper instance.
+type ConfigGetter interface {
+	GetString(key string) string
+	GetInt(key string) int
+	GetBool(key string) bool
+}
+
+// Logger is the subset of structured logging a storage backend needs,
+// kept as an interface so backends don't depend on logrus directly.
+type Logger interface {
+	As(level string) LogEntry
+}
+
+// LogEntry is a single, chainable structured log line.
+type LogEntry interface {
+	WithError(err error) LogEntry
+	WithField(key string, value interface{}) LogEntry
+	Logf(format string, args ...interface{})
+}
+
+// TwitterStore is the full CRUD+graph interface a storage backend must
+// implement to back the crawler. pkg/services/postgres, pkg/sqlite and
+// pkg/memstore each provide one.
+type TwitterStore interface {
+	GetProfile(username string) (*TwitterProfile, error)
+	SaveProfiles(profiles []*TwitterProfile) error
+	AddFollowings(profile *TwitterProfile, profiles []*TwitterProfile) error
+	AddFollowers(profile *TwitterProfile, profiles []*TwitterProfile) error
+	GetFollowings(username string) ([]*TwitterProfile, error)
+	GetFollowers(username string) ([]*TwitterProfile, error)
+	GetTopFollowingsByFollowers(username string, limit int) ([]*TwitterProfile, error)
+	GetTopFollowersByFollowers(username string, limit int) ([]*TwitterProfile, error)
+}
@@ -0,0 +1,148 @@
+// Package memstore is a goroutine-safe, in-memory entities.TwitterStore
+// implementation, primarily intended for tests.
+package memstore
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mehdy/twixter/pkg/entities"
+)
+
+// Store is an in-memory entities.TwitterStore. The zero value is not
+// usable; construct with New.
+type Store struct {
+	mu         sync.RWMutex
+	profiles   map[string]*entities.TwitterProfile // keyed by username
+	followings map[string]map[string]struct{}      // username -> set of usernames they follow
+	followers  map[string]map[string]struct{}      // username -> set of usernames following them
+}
+
+// New builds an empty Store.
+func New() *Store {
+	return &Store{
+		profiles:   map[string]*entities.TwitterProfile{},
+		followings: map[string]map[string]struct{}{},
+		followers:  map[string]map[string]struct{}{},
+	}
+}
+
+func (s *Store) GetProfile(username string) (*entities.TwitterProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	profile, ok := s.profiles[username]
+	if !ok {
+		return nil, fmt.Errorf("memstore: profile %q not found", username)
+	}
+
+	copied := *profile
+
+	return &copied, nil
+}
+
+func (s *Store) SaveProfiles(profiles []*entities.TwitterProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range profiles {
+		copied := *p
+		s.profiles[p.Username] = &copied
+	}
+
+	return nil
+}
+
+func (s *Store) AddFollowings(profile *entities.TwitterProfile, profiles []*entities.TwitterProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, ok := s.followings[profile.Username]
+	if !ok {
+		set = map[string]struct{}{}
+		s.followings[profile.Username] = set
+	}
+
+	for _, p := range profiles {
+		set[p.Username] = struct{}{}
+	}
+
+	return nil
+}
+
+func (s *Store) AddFollowers(profile *entities.TwitterProfile, profiles []*entities.TwitterProfile) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	set, ok := s.followers[profile.Username]
+	if !ok {
+		set = map[string]struct{}{}
+		s.followers[profile.Username] = set
+	}
+
+	for _, p := range profiles {
+		set[p.Username] = struct{}{}
+	}
+
+	return nil
+}
+
+func (s *Store) GetFollowings(username string) ([]*entities.TwitterProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.resolveLocked(s.followings[username]), nil
+}
+
+func (s *Store) GetFollowers(username string) ([]*entities.TwitterProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.resolveLocked(s.followers[username]), nil
+}
+
+func (s *Store) GetTopFollowingsByFollowers(username string, limit int) ([]*entities.TwitterProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.topByFollowersLocked(s.followings[username], limit), nil
+}
+
+func (s *Store) GetTopFollowersByFollowers(username string, limit int) ([]*entities.TwitterProfile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.topByFollowersLocked(s.followers[username], limit), nil
+}
+
+// resolveLocked maps a set of usernames to profiles. Callers must hold
+// s.mu for reading.
+func (s *Store) resolveLocked(usernames map[string]struct{}) []*entities.TwitterProfile {
+	profiles := make([]*entities.TwitterProfile, 0, len(usernames))
+	for username := range usernames {
+		if profile, ok := s.profiles[username]; ok {
+			copied := *profile
+			profiles = append(profiles, &copied)
+		}
+	}
+
+	return profiles
+}
+
+// topByFollowersLocked is resolveLocked sorted by descending FollowersCount
+// and capped at limit. Callers must hold s.mu for reading.
+func (s *Store) topByFollowersLocked(usernames map[string]struct{}, limit int) []*entities.TwitterProfile {
+	profiles := s.resolveLocked(usernames)
+
+	for i := 1; i < len(profiles); i++ {
+		for j := i; j > 0 && profiles[j].FollowersCount > profiles[j-1].FollowersCount; j-- {
+			profiles[j], profiles[j-1] = profiles[j-1], profiles[j]
+		}
+	}
+
+	if limit > 0 && len(profiles) > limit {
+		profiles = profiles[:limit]
+	}
+
+	return profiles
+}
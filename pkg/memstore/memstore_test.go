@@ -0,0 +1,105 @@
+package memstore
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/mehdy/twixter/pkg/entities"
+)
+
+func TestStoreSaveAndGetProfile(t *testing.T) {
+	s := New()
+
+	profile := &entities.TwitterProfile{Username: "alice", FollowersCount: 10}
+	if err := s.SaveProfiles([]*entities.TwitterProfile{profile}); err != nil {
+		t.Fatalf("SaveProfiles() error = %v", err)
+	}
+
+	got, err := s.GetProfile("alice")
+	if err != nil {
+		t.Fatalf("GetProfile() error = %v", err)
+	}
+
+	if got.Username != "alice" || got.FollowersCount != 10 {
+		t.Fatalf("GetProfile() = %+v, want username=alice followersCount=10", got)
+	}
+}
+
+func TestStoreGetProfileNotFound(t *testing.T) {
+	s := New()
+
+	if _, err := s.GetProfile("missing"); err == nil {
+		t.Fatal("GetProfile() error = nil, want not-found error")
+	}
+}
+
+func TestStoreAddFollowingsAndGet(t *testing.T) {
+	s := New()
+
+	alice := &entities.TwitterProfile{Username: "alice"}
+	bob := &entities.TwitterProfile{Username: "bob", FollowersCount: 5}
+	carol := &entities.TwitterProfile{Username: "carol", FollowersCount: 50}
+
+	if err := s.SaveProfiles([]*entities.TwitterProfile{alice, bob, carol}); err != nil {
+		t.Fatalf("SaveProfiles() error = %v", err)
+	}
+
+	if err := s.AddFollowings(alice, []*entities.TwitterProfile{bob, carol}); err != nil {
+		t.Fatalf("AddFollowings() error = %v", err)
+	}
+
+	followings, err := s.GetFollowings("alice")
+	if err != nil {
+		t.Fatalf("GetFollowings() error = %v", err)
+	}
+
+	if len(followings) != 2 {
+		t.Fatalf("GetFollowings() returned %d profiles, want 2", len(followings))
+	}
+
+	top, err := s.GetTopFollowingsByFollowers("alice", 1)
+	if err != nil {
+		t.Fatalf("GetTopFollowingsByFollowers() error = %v", err)
+	}
+
+	if len(top) != 1 || top[0].Username != "carol" {
+		t.Fatalf("GetTopFollowingsByFollowers() = %+v, want [carol]", top)
+	}
+}
+
+// TestStoreConcurrentAccess exercises AddFollowings/AddFollowers alongside
+// GetFollowings/GetFollowers/GetTop* under `go test -race` to guard the
+// goroutine-safety the package doc comment promises.
+func TestStoreConcurrentAccess(t *testing.T) {
+	s := New()
+
+	alice := &entities.TwitterProfile{Username: "alice"}
+	if err := s.SaveProfiles([]*entities.TwitterProfile{alice}); err != nil {
+		t.Fatalf("SaveProfiles() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			p := &entities.TwitterProfile{Username: "follower", FollowersCount: i}
+			_ = s.AddFollowings(alice, []*entities.TwitterProfile{p})
+			_ = s.AddFollowers(alice, []*entities.TwitterProfile{p})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			_, _ = s.GetFollowings("alice")
+			_, _ = s.GetFollowers("alice")
+			_, _ = s.GetTopFollowingsByFollowers("alice", 10)
+			_, _ = s.GetTopFollowersByFollowers("alice", 10)
+		}()
+	}
+
+	wg.Wait()
+}
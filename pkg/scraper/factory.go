@@ -0,0 +1,18 @@
+package scraper
+
+import (
+	"github.com/mehdy/twixter"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// NewFetcher builds a twixter.Fetcher, choosing between the official API
+// client and the scraper fallback based on the twitter.fetcher config key
+// ("api", the default, or "scraper").
+func NewFetcher(config *viper.Viper, log *logrus.Logger) (twixter.Fetcher, error) {
+	if config.GetString("twitter.fetcher") == "scraper" {
+		return New(config, log)
+	}
+
+	return twixter.NewTwitter(config, log), nil
+}
@@ -0,0 +1,332 @@
+// Package scraper is a Fetcher fallback for users without paid Twitter API
+// access. It drives the public web GraphQL/syndication endpoints instead
+// of api.twitter.com, using a guest token for read-only access and an
+// optional cookie jar when the caller supplies auth_token/ct0 cookies.
+package scraper
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mehdy/twixter"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+const (
+	// nolint: gosec // public, well-known bearer token for guest auth, not a secret
+	guestBearerToken = "AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs%3D1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA"
+	activateGuestURL = "https://api.twitter.com/1.1/guest/activate.json"
+	graphQLBaseURL   = "https://twitter.com/i/api/graphql"
+	userByScreenName = "UserByScreenName"
+	followingQuery   = "Following"
+	followersQuery   = "Followers"
+	maxBackoffTries  = 5
+)
+
+// Scraper is a twixter.Fetcher implementation backed by Twitter's public
+// web endpoints rather than api.twitter.com.
+type Scraper struct {
+	http       *http.Client
+	log        *logrus.Logger
+	guestMu    sync.Mutex
+	guestToken string
+	authToken  string
+	ct0        string
+}
+
+// New builds a Scraper. If config has twitter.scraper.auth_token/ct0 set,
+// requests are made as that authenticated session (raising rate limits);
+// otherwise requests use guest auth only.
+func New(config *viper.Viper, log *logrus.Logger) (*Scraper, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build cookie jar: %w", err)
+	}
+
+	authToken := config.GetString("twitter.scraper.auth_token")
+	ct0 := config.GetString("twitter.scraper.ct0")
+
+	if authToken != "" {
+		cookieURL, err := url.Parse("https://twitter.com")
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cookie domain: %w", err)
+		}
+
+		jar.SetCookies(cookieURL, []*http.Cookie{
+			{Name: "auth_token", Value: authToken},
+			{Name: "ct0", Value: ct0},
+		})
+	}
+
+	return &Scraper{
+		http:      &http.Client{Jar: jar},
+		log:       log,
+		authToken: authToken,
+		ct0:       ct0,
+	}, nil
+}
+
+// GetProfile fetches username's profile via the UserByScreenName GraphQL
+// query.
+func (s *Scraper) GetProfile(username string) (*twixter.TwitterProfile, error) {
+	var body struct {
+		Data struct {
+			User struct {
+				Result userResult `json:"result"`
+			} `json:"user"`
+		} `json:"data"`
+	}
+
+	reqURL := fmt.Sprintf("%s/%s?variables=%s", graphQLBaseURL, userByScreenName,
+		url.QueryEscape(fmt.Sprintf(`{"screen_name":%q}`, username)))
+
+	if err := s.getJSON(reqURL, &body); err != nil {
+		return nil, fmt.Errorf("failed to fetch profile for %q from scraper: %w", username, err)
+	}
+
+	return body.Data.User.Result.toTwitterProfile(), nil
+}
+
+// GetFollowings walks every page of username's followings via the
+// Following GraphQL query, following the bottom_cursor entry until
+// exhausted.
+func (s *Scraper) GetFollowings(username string) []*twixter.TwitterProfile {
+	return s.paginateConnections(username, followingQuery)
+}
+
+// GetFollowers is the GetFollowings counterpart for username's followers.
+func (s *Scraper) GetFollowers(username string) []*twixter.TwitterProfile {
+	return s.paginateConnections(username, followersQuery)
+}
+
+func (s *Scraper) paginateConnections(username, query string) []*twixter.TwitterProfile {
+	profiles := []*twixter.TwitterProfile{}
+	cursor := ""
+
+	for {
+		page, nextCursor, err := s.fetchConnectionsPage(username, query, cursor)
+		if err != nil {
+			s.log.WithError(err).Errorf("scraper: failed to fetch %s page for %q", query, username)
+
+			return profiles
+		}
+
+		profiles = append(profiles, page...)
+
+		if nextCursor == "" || nextCursor == cursor {
+			return profiles
+		}
+
+		cursor = nextCursor
+	}
+}
+
+// connectionsPage is the wire shape of a Following/Followers GraphQL
+// timeline response.
+type connectionsPage struct {
+	Timeline struct {
+		Instructions []struct {
+			Entries []struct {
+				Content struct {
+					User       *userResult `json:"user"`
+					CursorType string      `json:"cursorType"`
+					Value      string      `json:"value"`
+					EntryType  string      `json:"entryType"`
+				} `json:"content"`
+			} `json:"entries"`
+		} `json:"instructions"`
+	} `json:"timeline"`
+}
+
+func (s *Scraper) fetchConnectionsPage(username, query, cursor string) ([]*twixter.TwitterProfile, string, error) {
+	variables := fmt.Sprintf(`{"screen_name":%q,"cursor":%q,"count":20}`, username, cursor)
+	reqURL := fmt.Sprintf("%s/%s?variables=%s", graphQLBaseURL, query, url.QueryEscape(variables))
+
+	var body connectionsPage
+	if err := s.getJSON(reqURL, &body); err != nil {
+		return nil, "", err
+	}
+
+	profiles, nextCursor := parseConnectionsPage(body)
+
+	return profiles, nextCursor, nil
+}
+
+// parseConnectionsPage extracts the user entries and the "Bottom" pagination
+// cursor out of a decoded connectionsPage.
+func parseConnectionsPage(body connectionsPage) ([]*twixter.TwitterProfile, string) {
+	profiles := []*twixter.TwitterProfile{}
+	nextCursor := ""
+
+	for _, instruction := range body.Timeline.Instructions {
+		for _, entry := range instruction.Entries {
+			if entry.Content.User != nil {
+				profiles = append(profiles, entry.Content.User.toTwitterProfile())
+			}
+
+			if entry.Content.CursorType == "Bottom" {
+				nextCursor = entry.Content.Value
+			}
+		}
+	}
+
+	return profiles, nextCursor
+}
+
+// getJSON performs an authenticated GET, activating a guest token on
+// demand and retrying with exponential backoff on 429.
+func (s *Scraper) getJSON(url string, out interface{}) error {
+	for attempt := 0; attempt < maxBackoffTries; attempt++ {
+		token, err := s.currentGuestToken()
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to build scraper request: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+guestBearerToken)
+		req.Header.Set("x-guest-token", token)
+
+		if s.authToken != "" {
+			req.Header.Set("x-csrf-token", s.ct0)
+		}
+
+		resp, err := s.http.Do(req)
+		if err != nil {
+			return fmt.Errorf("scraper request failed: %w", err)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			defer resp.Body.Close()
+
+			return json.NewDecoder(resp.Body).Decode(out)
+		case http.StatusTooManyRequests:
+			resp.Body.Close()
+			time.Sleep(backoff(attempt))
+
+			continue
+		case http.StatusUnauthorized, http.StatusForbidden:
+			resp.Body.Close()
+			s.invalidateGuestToken()
+
+			continue
+		default:
+			resp.Body.Close()
+
+			return fmt.Errorf("scraper request returned unexpected status %d", resp.StatusCode)
+		}
+	}
+
+	return fmt.Errorf("scraper request exhausted %d retries", maxBackoffTries)
+}
+
+// currentGuestToken returns the cached guest token, activating a new one
+// under guestMu if none is cached yet.
+func (s *Scraper) currentGuestToken() (string, error) {
+	s.guestMu.Lock()
+	defer s.guestMu.Unlock()
+
+	if s.guestToken != "" {
+		return s.guestToken, nil
+	}
+
+	token, err := s.activateGuestToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.guestToken = token
+
+	return token, nil
+}
+
+func (s *Scraper) invalidateGuestToken() {
+	s.guestMu.Lock()
+	defer s.guestMu.Unlock()
+
+	s.guestToken = ""
+}
+
+func (s *Scraper) activateGuestToken() (string, error) {
+	req, err := http.NewRequest(http.MethodPost, activateGuestURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to build guest activation request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+guestBearerToken)
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to activate guest token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		GuestToken string `json:"guest_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode guest token response: %w", err)
+	}
+
+	return body.GuestToken, nil
+}
+
+// backoff returns an exponential backoff duration for the given (0-based)
+// retry attempt, capped at 30 seconds.
+func backoff(attempt int) time.Duration {
+	d := time.Duration(math.Pow(2, float64(attempt))) * time.Second
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+
+	return d
+}
+
+// userResult is the wire shape of a GraphQL `user.result` object.
+type userResult struct {
+	RestID string `json:"rest_id"`
+	Legacy struct {
+		Name            string `json:"name"`
+		ScreenName      string `json:"screen_name"`
+		Description     string `json:"description"`
+		URL             string `json:"url"`
+		ProfileImageURL string `json:"profile_image_url_https"`
+		Verified        bool   `json:"verified"`
+		Protected       bool   `json:"protected"`
+		FollowersCount  int    `json:"followers_count"`
+		FriendsCount    int    `json:"friends_count"`
+		FavouritesCount int    `json:"favourites_count"`
+		ListedCount     int    `json:"listed_count"`
+		StatusesCount   int    `json:"statuses_count"`
+	} `json:"legacy"`
+}
+
+func (u userResult) toTwitterProfile() *twixter.TwitterProfile {
+	return &twixter.TwitterProfile{
+		TwitterID:       u.RestID,
+		Name:            u.Legacy.Name,
+		Username:        u.Legacy.ScreenName,
+		Bio:             u.Legacy.Description,
+		URL:             u.Legacy.URL,
+		ProfileImageURL: u.Legacy.ProfileImageURL,
+		Verified:        u.Legacy.Verified,
+		Protected:       u.Legacy.Protected,
+		FollowersCount:  u.Legacy.FollowersCount,
+		FollowingsCount: u.Legacy.FriendsCount,
+		FavouritesCount: u.Legacy.FavouritesCount,
+		ListedCount:     u.Legacy.ListedCount,
+		TweetsCount:     u.Legacy.StatusesCount,
+	}
+}
@@ -0,0 +1,82 @@
+package scraper
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBackoffGrowsExponentiallyAndCaps(t *testing.T) {
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: 1 * time.Second},
+		{attempt: 1, want: 2 * time.Second},
+		{attempt: 2, want: 4 * time.Second},
+		{attempt: 5, want: 30 * time.Second},
+		{attempt: 10, want: 30 * time.Second},
+	}
+
+	for _, c := range cases {
+		if got := backoff(c.attempt); got != c.want {
+			t.Errorf("backoff(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestParseConnectionsPageExtractsUsersAndCursor(t *testing.T) {
+	raw := `{
+		"timeline": {
+			"instructions": [{
+				"entries": [
+					{"content": {"user": {"rest_id": "1", "legacy": {"screen_name": "bob"}}, "entryType": "TimelineTimelineItem"}},
+					{"content": {"user": {"rest_id": "2", "legacy": {"screen_name": "carol"}}, "entryType": "TimelineTimelineItem"}},
+					{"content": {"cursorType": "Bottom", "value": "cursor-123", "entryType": "TimelineTimelineCursor"}}
+				]
+			}]
+		}
+	}`
+
+	var body connectionsPage
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	profiles, nextCursor := parseConnectionsPage(body)
+
+	if len(profiles) != 2 || profiles[0].Username != "bob" || profiles[1].Username != "carol" {
+		t.Fatalf("parseConnectionsPage() profiles = %+v, want [bob carol]", profiles)
+	}
+
+	if nextCursor != "cursor-123" {
+		t.Fatalf("parseConnectionsPage() nextCursor = %q, want %q", nextCursor, "cursor-123")
+	}
+}
+
+func TestParseConnectionsPageNoBottomCursor(t *testing.T) {
+	raw := `{
+		"timeline": {
+			"instructions": [{
+				"entries": [
+					{"content": {"user": {"rest_id": "1", "legacy": {"screen_name": "bob"}}, "entryType": "TimelineTimelineItem"}}
+				]
+			}]
+		}
+	}`
+
+	var body connectionsPage
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	profiles, nextCursor := parseConnectionsPage(body)
+
+	if len(profiles) != 1 {
+		t.Fatalf("parseConnectionsPage() profiles = %+v, want 1 entry", profiles)
+	}
+
+	if nextCursor != "" {
+		t.Fatalf("parseConnectionsPage() nextCursor = %q, want empty when no Bottom cursor entry is present", nextCursor)
+	}
+}
@@ -0,0 +1,28 @@
+// Package services wires together the pluggable entities.TwitterStore
+// backends so callers can select one by configuration instead of importing
+// a concrete driver.
+package services
+
+import (
+	"fmt"
+
+	"github.com/mehdy/twixter/pkg/entities"
+	"github.com/mehdy/twixter/pkg/memstore"
+	"github.com/mehdy/twixter/pkg/services/postgres"
+	"github.com/mehdy/twixter/pkg/sqlite"
+)
+
+// NewTwitterStore builds the entities.TwitterStore selected by the
+// storage.driver config key ("postgres", "sqlite" or "memory").
+func NewTwitterStore(config entities.ConfigGetter, logger entities.Logger) (entities.TwitterStore, error) {
+	switch driver := config.GetString("storage.driver"); driver {
+	case "", "postgres":
+		return postgres.NewTwitter(config, logger), nil
+	case "sqlite":
+		return sqlite.New(config, logger)
+	case "memory":
+		return memstore.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage.driver %q", driver)
+	}
+}
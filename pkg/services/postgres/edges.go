@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowEdge is the gorm model backing the follow_edges table: one row per
+// observed (follower, followee) pair, with first/last seen timestamps so a
+// re-crawl only ever updates last_seen_at instead of rewriting the join
+// table.
+type FollowEdge struct {
+	FollowerID  string `gorm:"primaryKey;column:follower_id"`
+	FolloweeID  string `gorm:"primaryKey;column:followee_id"`
+	FirstSeenAt time.Time
+	LastSeenAt  time.Time
+}
+
+func (FollowEdge) TableName() string {
+	return "follow_edges"
+}
+
+// SaveFollowEdges records that followerID follows each of followeeIDs,
+// upserting all edges in a single batched INSERT ... ON CONFLICT DO UPDATE
+// inside one transaction, so crawling a 100k+ follower account doesn't
+// rewrite the whole join table on every call.
+func (t *Twitter) SaveFollowEdges(followerID string, followeeIDs []string) error {
+	now := time.Now()
+
+	edges := make([]FollowEdge, 0, len(followeeIDs))
+	for _, followeeID := range followeeIDs {
+		edges = append(edges, FollowEdge{
+			FollowerID:  followerID,
+			FolloweeID:  followeeID,
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		})
+	}
+
+	if err := t.saveFollowEdgeRows(edges); err != nil {
+		t.logger.As("E").WithError(err).WithField("followerID", followerID).Logf("Failed to save follow edges in database")
+
+		return newError(err, "failed to save follow edges in database")
+	}
+
+	return nil
+}
+
+// saveFollowersOf records that each of followerIDs follows followeeID, the
+// mirror of SaveFollowEdges for when the crawl walked followeeID's
+// followers list instead of a user's followings.
+func (t *Twitter) saveFollowersOf(followeeID string, followerIDs []string) error {
+	now := time.Now()
+
+	edges := make([]FollowEdge, 0, len(followerIDs))
+	for _, followerID := range followerIDs {
+		edges = append(edges, FollowEdge{
+			FollowerID:  followerID,
+			FolloweeID:  followeeID,
+			FirstSeenAt: now,
+			LastSeenAt:  now,
+		})
+	}
+
+	if err := t.saveFollowEdgeRows(edges); err != nil {
+		t.logger.As("E").WithError(err).WithField("followeeID", followeeID).Logf("Failed to save follower edges in database")
+
+		return newError(err, "failed to save follower edges in database")
+	}
+
+	return nil
+}
+
+// saveFollowEdgeRows upserts edges in a single batched
+// INSERT ... ON CONFLICT DO UPDATE inside one transaction.
+func (t *Twitter) saveFollowEdgeRows(edges []FollowEdge) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "follower_id"}, {Name: "followee_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_seen_at"}),
+		}).CreateInBatches(edges, saveProfilesBatchSize).Error
+	})
+}
@@ -0,0 +1,22 @@
+package postgres
+
+import "fmt"
+
+// storeError wraps a lower-level gorm/db error with a user-facing message
+// while keeping the original error chain intact for errors.Is/As callers.
+type storeError struct {
+	msg string
+	err error
+}
+
+func newError(err error, msg string) error {
+	return &storeError{msg: msg, err: err}
+}
+
+func (e *storeError) Error() string {
+	return fmt.Sprintf("%s: %v", e.msg, e.err)
+}
+
+func (e *storeError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,271 @@
+package postgres
+
+import (
+	"math"
+
+	"github.com/mehdy/twixter/pkg/entities"
+)
+
+const (
+	pageRankDamping     = 0.85
+	pageRankIterations  = 20
+	pageRankConvergence = 1e-4
+)
+
+// GetMutuals returns the profiles that both follow and are followed by
+// username, i.e. the intersection of username's followings and followers.
+func (t *Twitter) GetMutuals(username string) ([]*entities.TwitterProfile, error) {
+	profiles := []*TwitterProfile{}
+	if err := t.db.
+		Joins("JOIN follow_edges AS following ON following.followee_id = twitter_profiles.twitter_id").
+		Joins("JOIN follow_edges AS follower ON follower.follower_id = twitter_profiles.twitter_id").
+		Joins("JOIN twitter_profiles AS owner ON owner.twitter_id = following.follower_id AND owner.twitter_id = follower.followee_id").
+		Where("owner.username = ?", username).
+		Find(&profiles).Error; err != nil {
+		t.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to get mutuals")
+
+		return nil, newError(err, "failed to get mutuals")
+	}
+
+	results := []*entities.TwitterProfile{}
+	for _, profile := range profiles {
+		results = append(results, t.asTwitterProfile(profile))
+	}
+
+	return results, nil
+}
+
+// GetSecondDegreeFollowings returns the profiles reachable by following
+// username's followings for up to depth hops, excluding username's own
+// direct followings.
+func (t *Twitter) GetSecondDegreeFollowings(username string, depth int) ([]*entities.TwitterProfile, error) {
+	type row struct {
+		TwitterProfile
+		Depth int
+	}
+
+	rows := []row{}
+
+	query := `
+		WITH RECURSIVE reachable(twitter_id, depth) AS (
+			SELECT fe.followee_id, 1
+			FROM follow_edges fe
+			JOIN twitter_profiles owner ON owner.twitter_id = fe.follower_id
+			WHERE owner.username = ?
+
+			UNION
+
+			SELECT fe.followee_id, r.depth + 1
+			FROM follow_edges fe
+			JOIN reachable r ON r.twitter_id = fe.follower_id
+			WHERE r.depth < ?
+		)
+		SELECT tp.*, r.depth AS depth
+		FROM reachable r
+		JOIN twitter_profiles tp ON tp.twitter_id = r.twitter_id
+		WHERE r.depth > 1
+	`
+
+	if err := t.db.Raw(query, username, depth).Scan(&rows).Error; err != nil {
+		t.logger.
+			As("E").
+			WithError(err).
+			WithField("username", username).
+			WithField("depth", depth).
+			Logf("Failed to get second degree followings")
+
+		return nil, newError(err, "failed to get second degree followings")
+	}
+
+	results := []*entities.TwitterProfile{}
+	for _, r := range rows {
+		profile := r.TwitterProfile
+		results = append(results, t.asTwitterProfile(&profile))
+	}
+
+	return results, nil
+}
+
+// GetTopByPageRank runs a bounded PageRank over the follow-graph subgraph
+// reachable within depth hops of username, and returns the top limit
+// profiles by rank. This surfaces the influencers within username's
+// network rather than just the loudest accounts.
+func (t *Twitter) GetTopByPageRank(username string, depth, limit int) ([]*entities.TwitterProfile, error) {
+	adjacency, profiles, err := t.subgraphAdjacency(username, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	ranks := pageRank(adjacency)
+
+	ordered := make([]string, 0, len(ranks))
+	for id := range ranks {
+		ordered = append(ordered, id)
+	}
+
+	sortByRankDesc(ordered, ranks)
+
+	if limit > 0 && len(ordered) > limit {
+		ordered = ordered[:limit]
+	}
+
+	results := make([]*entities.TwitterProfile, 0, len(ordered))
+	for _, id := range ordered {
+		if profile, ok := profiles[id]; ok {
+			results = append(results, t.asTwitterProfile(profile))
+		}
+	}
+
+	return results, nil
+}
+
+// subgraphAdjacency loads the follow-graph subgraph reachable within depth
+// hops of username as an out-edge adjacency map keyed by twitter_id, along
+// with the TwitterProfile for every node in that subgraph.
+func (t *Twitter) subgraphAdjacency(username string, depth int) (map[string][]string, map[string]*TwitterProfile, error) {
+	type edge struct {
+		From string
+		To   string
+	}
+
+	edges := []edge{}
+
+	query := `
+		WITH RECURSIVE reachable(twitter_id, depth) AS (
+			SELECT owner.twitter_id, 0
+			FROM twitter_profiles owner
+			WHERE owner.username = ?
+
+			UNION
+
+			SELECT fe.followee_id, r.depth + 1
+			FROM follow_edges fe
+			JOIN reachable r ON r.twitter_id = fe.follower_id
+			WHERE r.depth < ?
+		)
+		SELECT fe.follower_id AS "from", fe.followee_id AS "to"
+		FROM follow_edges fe
+		JOIN reachable r ON r.twitter_id = fe.follower_id
+	`
+
+	if err := t.db.Raw(query, username, depth).Scan(&edges).Error; err != nil {
+		t.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to load follow subgraph")
+
+		return nil, nil, newError(err, "failed to load follow subgraph")
+	}
+
+	adjacency := map[string][]string{}
+	nodeIDs := map[string]struct{}{}
+
+	for _, e := range edges {
+		adjacency[e.From] = append(adjacency[e.From], e.To)
+		nodeIDs[e.From] = struct{}{}
+		nodeIDs[e.To] = struct{}{}
+	}
+
+	ids := make([]string, 0, len(nodeIDs))
+	for id := range nodeIDs {
+		ids = append(ids, id)
+	}
+
+	profileRows := []TwitterProfile{}
+	if len(ids) > 0 {
+		if err := t.db.Where("twitter_id IN ?", ids).Find(&profileRows).Error; err != nil {
+			t.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to load subgraph profiles")
+
+			return nil, nil, newError(err, "failed to load subgraph profiles")
+		}
+	}
+
+	profiles := make(map[string]*TwitterProfile, len(profileRows))
+	for i := range profileRows {
+		profiles[profileRows[i].TwitterID] = &profileRows[i]
+	}
+
+	return adjacency, profiles, nil
+}
+
+// pageRank runs a bounded, iterative PageRank over adjacency (an out-edge
+// map) and returns the final rank of every node, damping=0.85 over at most
+// 20 iterations, stopping early once the max rank delta falls below 1e-4.
+func pageRank(adjacency map[string][]string) map[string]float64 {
+	nodes := map[string]struct{}{}
+	outDegree := map[string]int{}
+
+	for from, tos := range adjacency {
+		nodes[from] = struct{}{}
+		outDegree[from] = len(tos)
+
+		for _, to := range tos {
+			nodes[to] = struct{}{}
+		}
+	}
+
+	n := len(nodes)
+	if n == 0 {
+		return map[string]float64{}
+	}
+
+	inbound := map[string][]string{}
+	for from, tos := range adjacency {
+		for _, to := range tos {
+			inbound[to] = append(inbound[to], from)
+		}
+	}
+
+	ranks := make(map[string]float64, n)
+	for node := range nodes {
+		ranks[node] = 1.0 / float64(n)
+	}
+
+	for iter := 0; iter < pageRankIterations; iter++ {
+		// Dangling nodes (no out-edges, e.g. a sink or a subgraph boundary
+		// leaf) have nowhere to forward their rank to. Redistributing their
+		// mass evenly across every node is the standard PageRank fix;
+		// without it a dangling node just accumulates rank forever and
+		// outranks real hubs that forward their mass onward.
+		danglingMass := 0.0
+		for node := range nodes {
+			if outDegree[node] == 0 {
+				danglingMass += ranks[node]
+			}
+		}
+
+		next := make(map[string]float64, n)
+		maxDelta := 0.0
+
+		for node := range nodes {
+			sum := danglingMass / float64(n)
+			for _, u := range inbound[node] {
+				if outDegree[u] > 0 {
+					sum += ranks[u] / float64(outDegree[u])
+				}
+			}
+
+			r := (1-pageRankDamping)/float64(n) + pageRankDamping*sum
+			if delta := math.Abs(r - ranks[node]); delta > maxDelta {
+				maxDelta = delta
+			}
+
+			next[node] = r
+		}
+
+		ranks = next
+		if maxDelta < pageRankConvergence {
+			break
+		}
+	}
+
+	return ranks
+}
+
+// sortByRankDesc sorts ids in place by descending ranks[id], using a plain
+// insertion sort since subgraphs are small enough that clarity wins over
+// asymptotic complexity here.
+func sortByRankDesc(ids []string, ranks map[string]float64) {
+	for i := 1; i < len(ids); i++ {
+		for j := i; j > 0 && ranks[ids[j]] > ranks[ids[j-1]]; j-- {
+			ids[j], ids[j-1] = ids[j-1], ids[j]
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPageRankRanksHubAboveLeaf(t *testing.T) {
+	// a -> hub, b -> hub, hub -> a, hub -> b, hub -> c: hub is reciprocally
+	// linked by its two feeders and also points at dangling leaf c, so it
+	// should end up ranked above both the leaf and its feeders.
+	adjacency := map[string][]string{
+		"a":   {"hub"},
+		"b":   {"hub"},
+		"hub": {"a", "b", "c"},
+	}
+
+	ranks := pageRank(adjacency)
+
+	if ranks["hub"] <= ranks["c"] {
+		t.Fatalf("rank(hub)=%v want > rank(c)=%v", ranks["hub"], ranks["c"])
+	}
+
+	if ranks["hub"] <= ranks["a"] {
+		t.Fatalf("rank(hub)=%v want > rank(a)=%v", ranks["hub"], ranks["a"])
+	}
+}
+
+func TestPageRankSumsToApproximatelyOne(t *testing.T) {
+	adjacency := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+		"c": {"a"},
+	}
+
+	ranks := pageRank(adjacency)
+
+	sum := 0.0
+	for _, r := range ranks {
+		sum += r
+	}
+
+	if math.Abs(sum-1.0) > 1e-2 {
+		t.Fatalf("sum(ranks) = %v, want ~1.0", sum)
+	}
+}
+
+func TestPageRankEmptyGraph(t *testing.T) {
+	if ranks := pageRank(map[string][]string{}); len(ranks) != 0 {
+		t.Fatalf("pageRank(empty) = %v, want empty map", ranks)
+	}
+}
+
+func TestSortByRankDesc(t *testing.T) {
+	ids := []string{"a", "b", "c"}
+	ranks := map[string]float64{"a": 0.1, "b": 0.9, "c": 0.5}
+
+	sortByRankDesc(ids, ranks)
+
+	want := []string{"b", "c", "a"}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("sortByRankDesc() = %v, want %v", ids, want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TwitterProfile is the gorm model backing the twitter_profiles table. It
+// mirrors entities.TwitterProfile field-for-field (see
+// fromTwitterProfile/asTwitterProfile) plus the gorm-specific bits: a
+// surrogate primary key and a unique twitter_id. The follow graph itself
+// lives in the follow_edges table (see edges.go/graph.go) rather than a
+// gorm many2many association, so it scales to batched upserts instead of
+// a FullSaveAssociations rewrite of the whole join table.
+type TwitterProfile struct {
+	gorm.Model
+
+	TwitterID           string `gorm:"uniqueIndex"`
+	Name                string
+	Username            string `gorm:"index"`
+	Location            string
+	Bio                 string
+	URL                 string
+	Email               string
+	ProfileBannerURL    string
+	ProfileImageURL     string
+	Verified            bool
+	Protected           bool
+	DefaultProfile      bool
+	DefaultProfileImage bool
+	FollowersCount      int
+	FollowingsCount     int
+	FavouritesCount     int
+	ListedCount         int
+	TweetsCount         int
+	Entities            []byte
+	JoinedAt            time.Time
+}
@@ -7,8 +7,11 @@ import (
 	"github.com/mehdy/twixter/pkg/entities"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const saveProfilesBatchSize = 500
+
 type Twitter struct {
 	db     *gorm.DB
 	logger entities.Logger
@@ -58,7 +61,17 @@ func (t *Twitter) SaveProfiles(profiles []*entities.TwitterProfile) error {
 		tps = append(tps, t.fromTwitterProfile(tp))
 	}
 
-	if err := t.db.Create(tps).Error; err != nil {
+	err := t.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "twitter_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"name", "username", "location", "bio", "url", "email",
+			"profile_banner_url", "profile_image_url", "verified", "protected",
+			"default_profile", "default_profile_image", "followers_count",
+			"followings_count", "favourites_count", "listed_count", "tweets_count",
+			"entities", "joined_at",
+		}),
+	}).CreateInBatches(tps, saveProfilesBatchSize).Error
+	if err != nil {
 		t.logger.As("E").WithError(err).Logf("Failed to create Profiles in database")
 
 		return newError(err, "failed to save profiles in database")
@@ -67,15 +80,16 @@ func (t *Twitter) SaveProfiles(profiles []*entities.TwitterProfile) error {
 	return nil
 }
 
+// AddFollowings records that profile follows each of profiles. Edges are
+// upserted via SaveFollowEdges instead of a FullSaveAssociations update, so
+// this stays cheap no matter how large profile's followings list grows.
 func (t *Twitter) AddFollowings(profile *entities.TwitterProfile, profiles []*entities.TwitterProfile) error {
-	tp := t.fromTwitterProfile(profile)
-
+	followeeIDs := make([]string, 0, len(profiles))
 	for _, p := range profiles {
-		fp := t.fromTwitterProfile(p)
-		tp.Followings = append(tp.Followings, *fp)
+		followeeIDs = append(followeeIDs, p.TwitterID)
 	}
 
-	if err := t.db.Session(&gorm.Session{FullSaveAssociations: true}).Updates(tp).Error; err != nil {
+	if err := t.SaveFollowEdges(profile.TwitterID, followeeIDs); err != nil {
 		t.logger.As("E").
 			WithError(err).
 			WithField("username", profile.Username).
@@ -87,15 +101,16 @@ func (t *Twitter) AddFollowings(profile *entities.TwitterProfile, profiles []*en
 	return nil
 }
 
+// AddFollowers records that each of profiles follows profile. Edges are
+// upserted via saveFollowersOf instead of a FullSaveAssociations update, so
+// this stays cheap no matter how large profile's followers list grows.
 func (t *Twitter) AddFollowers(profile *entities.TwitterProfile, profiles []*entities.TwitterProfile) error {
-	tp := t.fromTwitterProfile(profile)
-
+	followerIDs := make([]string, 0, len(profiles))
 	for _, p := range profiles {
-		fp := t.fromTwitterProfile(p)
-		tp.Followers = append(tp.Followers, *fp)
+		followerIDs = append(followerIDs, p.TwitterID)
 	}
 
-	if err := t.db.Session(&gorm.Session{FullSaveAssociations: true}).Updates(tp).Error; err != nil {
+	if err := t.saveFollowersOf(profile.TwitterID, followerIDs); err != nil {
 		t.logger.As("E").
 			WithError(err).
 			WithField("username", profile.Username).
@@ -108,33 +123,54 @@ func (t *Twitter) AddFollowers(profile *entities.TwitterProfile, profiles []*ent
 }
 
 func (t *Twitter) GetFollowings(username string) ([]*entities.TwitterProfile, error) {
-	followings := []*entities.TwitterProfile{}
-	if err := t.db.Model(&TwitterProfile{Username: username}).
-		Association("Followings").Find(&followings); err != nil {
+	profiles := []*TwitterProfile{}
+	if err := t.db.
+		Joins("JOIN follow_edges ON follow_edges.followee_id = twitter_profiles.twitter_id").
+		Joins("JOIN twitter_profiles AS owner ON owner.twitter_id = follow_edges.follower_id").
+		Where("owner.username = ?", username).
+		Find(&profiles).Error; err != nil {
 		t.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to get followings from database")
 
 		return nil, newError(err, "failed to get followings from database")
 	}
 
-	return followings, nil
+	results := []*entities.TwitterProfile{}
+	for _, profile := range profiles {
+		results = append(results, t.asTwitterProfile(profile))
+	}
+
+	return results, nil
 }
 
 func (t *Twitter) GetFollowers(username string) ([]*entities.TwitterProfile, error) {
-	followers := []*entities.TwitterProfile{}
-	if err := t.db.Model(&TwitterProfile{Username: username}).
-		Association("Followings").Find(&followers); err != nil {
+	profiles := []*TwitterProfile{}
+	if err := t.db.
+		Joins("JOIN follow_edges ON follow_edges.follower_id = twitter_profiles.twitter_id").
+		Joins("JOIN twitter_profiles AS owner ON owner.twitter_id = follow_edges.followee_id").
+		Where("owner.username = ?", username).
+		Find(&profiles).Error; err != nil {
 		t.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to get followers from database")
 
 		return nil, newError(err, "failed to get followers from database")
 	}
 
-	return followers, nil
+	results := []*entities.TwitterProfile{}
+	for _, profile := range profiles {
+		results = append(results, t.asTwitterProfile(profile))
+	}
+
+	return results, nil
 }
 
 func (t *Twitter) GetTopFollowingsByFollowers(username string, limit int) ([]*entities.TwitterProfile, error) {
 	profiles := []*TwitterProfile{}
-	if err := t.db.Model(&TwitterProfile{Username: username}).
-		Association("Followings").Find(&profiles); err != nil {
+	if err := t.db.
+		Joins("JOIN follow_edges ON follow_edges.followee_id = twitter_profiles.twitter_id").
+		Joins("JOIN twitter_profiles AS owner ON owner.twitter_id = follow_edges.follower_id").
+		Where("owner.username = ?", username).
+		Order("twitter_profiles.followers_count DESC").
+		Limit(limit).
+		Find(&profiles).Error; err != nil {
 		t.logger.
 			As("E").
 			WithError(err).
@@ -155,8 +191,13 @@ func (t *Twitter) GetTopFollowingsByFollowers(username string, limit int) ([]*en
 
 func (t *Twitter) GetTopFollowersByFollowers(username string, limit int) ([]*entities.TwitterProfile, error) {
 	profiles := []*TwitterProfile{}
-	if err := t.db.Model(&TwitterProfile{Username: username}).
-		Association("Followers").Find(&profiles); err != nil {
+	if err := t.db.
+		Joins("JOIN follow_edges ON follow_edges.follower_id = twitter_profiles.twitter_id").
+		Joins("JOIN twitter_profiles AS owner ON owner.twitter_id = follow_edges.followee_id").
+		Where("owner.username = ?", username).
+		Order("twitter_profiles.followers_count DESC").
+		Limit(limit).
+		Find(&profiles).Error; err != nil {
 		t.logger.
 			As("E").
 			WithError(err).
@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"time"
+
+	"github.com/mehdy/twixter/pkg/timeline"
+)
+
+// FollowEvent is the gorm model backing the follow_events table: one row
+// per FollowAdded/FollowRemoved observed for a user.
+type FollowEvent struct {
+	ID             string `gorm:"primaryKey"`
+	Username       string `gorm:"index"`
+	TargetUsername string
+	Type           string
+	OccurredAt     time.Time
+}
+
+// ProfileRevision is the gorm model backing the profile_revisions table:
+// one row per ProfileUpdated observed for a user.
+type ProfileRevision struct {
+	ID         string `gorm:"primaryKey"`
+	Username   string `gorm:"index"`
+	OccurredAt time.Time
+	Profile    ProfileSnapshot `gorm:"embedded"`
+}
+
+// ProfileSnapshot is a revision-only copy of a profile's fields, embedded
+// into ProfileRevision. It deliberately does not embed TwitterProfile
+// itself: TwitterProfile carries gorm.Model plus a uniqueIndex on
+// twitter_id, and inheriting that index here would cap profile_revisions
+// at one row per user, defeating the point of a revision history.
+type ProfileSnapshot struct {
+	TwitterID           string
+	Name                string
+	Username            string
+	Location            string
+	Bio                 string
+	URL                 string
+	Email               string
+	ProfileBannerURL    string
+	ProfileImageURL     string
+	Verified            bool
+	Protected           bool
+	DefaultProfile      bool
+	DefaultProfileImage bool
+	FollowersCount      int
+	FollowingsCount     int
+	FavouritesCount     int
+	ListedCount         int
+	TweetsCount         int
+	Entities            []byte
+	JoinedAt            time.Time
+}
+
+// SaveFollowEvents persists FollowAdded/FollowRemoved timeline.Events into
+// the follow_events table, satisfying timeline.Store.
+func (t *Twitter) SaveFollowEvents(events []timeline.Event) error {
+	rows := make([]FollowEvent, 0, len(events))
+	for _, e := range events {
+		rows = append(rows, FollowEvent{
+			ID:             e.ID.String(),
+			Username:       e.Username,
+			TargetUsername: e.TargetUsername,
+			Type:           string(e.Type),
+			OccurredAt:     e.OccurredAt,
+		})
+	}
+
+	if err := t.db.Create(rows).Error; err != nil {
+		t.logger.As("E").WithError(err).Logf("Failed to save follow events in database")
+
+		return newError(err, "failed to save follow events in database")
+	}
+
+	return nil
+}
+
+// SaveProfileRevisions persists ProfileUpdated timeline.Events into the
+// profile_revisions table, satisfying timeline.Store.
+func (t *Twitter) SaveProfileRevisions(events []timeline.Event) error {
+	rows := make([]ProfileRevision, 0, len(events))
+	for _, e := range events {
+		rows = append(rows, ProfileRevision{
+			ID:         e.ID.String(),
+			Username:   e.Username,
+			OccurredAt: e.OccurredAt,
+			Profile:    toProfileSnapshot(t.fromTwitterProfile(e.Profile)),
+		})
+	}
+
+	if err := t.db.Create(rows).Error; err != nil {
+		t.logger.As("E").WithError(err).Logf("Failed to save profile revisions in database")
+
+		return newError(err, "failed to save profile revisions in database")
+	}
+
+	return nil
+}
+
+// toProfileSnapshot copies tp's fields into a ProfileSnapshot, dropping the
+// gorm.Model/uniqueIndex baggage that makes TwitterProfile unsuitable for
+// storing more than once per user.
+func toProfileSnapshot(tp *TwitterProfile) ProfileSnapshot {
+	return ProfileSnapshot{
+		TwitterID:           tp.TwitterID,
+		Name:                tp.Name,
+		Username:            tp.Username,
+		Location:            tp.Location,
+		Bio:                 tp.Bio,
+		URL:                 tp.URL,
+		Email:               tp.Email,
+		ProfileBannerURL:    tp.ProfileBannerURL,
+		ProfileImageURL:     tp.ProfileImageURL,
+		Verified:            tp.Verified,
+		Protected:           tp.Protected,
+		DefaultProfile:      tp.DefaultProfile,
+		DefaultProfileImage: tp.DefaultProfileImage,
+		FollowersCount:      tp.FollowersCount,
+		FollowingsCount:     tp.FollowingsCount,
+		FavouritesCount:     tp.FavouritesCount,
+		ListedCount:         tp.ListedCount,
+		TweetsCount:         tp.TweetsCount,
+		Entities:            tp.Entities,
+		JoinedAt:            tp.JoinedAt,
+	}
+}
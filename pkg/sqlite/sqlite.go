@@ -0,0 +1,271 @@
+// Package sqlite is a single-file entities.TwitterStore implementation,
+// backed by gorm.io/driver/sqlite. It mirrors pkg/services/postgres's
+// schema so crawls can run against a local db/ folder without a Postgres
+// server, which is enough for single-binary local crawls of small-to-medium
+// accounts.
+package sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mehdy/twixter/pkg/entities"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// saveProfilesBatchSize caps how many rows a single SaveProfiles upsert
+// statement covers, matching pkg/services/postgres's batching so a large
+// re-crawl doesn't build one giant INSERT.
+const saveProfilesBatchSize = 500
+
+// TwitterProfile is the gorm model backing the twitter_profiles table.
+type TwitterProfile struct {
+	gorm.Model
+
+	TwitterID           string `gorm:"uniqueIndex"`
+	Name                string
+	Username            string `gorm:"index"`
+	Location            string
+	Bio                 string
+	URL                 string
+	Email               string
+	ProfileBannerURL    string
+	ProfileImageURL     string
+	Verified            bool
+	Protected           bool
+	DefaultProfile      bool
+	DefaultProfileImage bool
+	FollowersCount      int
+	FollowingsCount     int
+	FavouritesCount     int
+	ListedCount         int
+	TweetsCount         int
+	Entities            []byte
+	JoinedAt            time.Time
+
+	Followings []*TwitterProfile `gorm:"many2many:sqlite_followings;"`
+	Followers  []*TwitterProfile `gorm:"many2many:sqlite_followers;"`
+}
+
+// Store is an entities.TwitterStore backed by a local SQLite file.
+type Store struct {
+	db     *gorm.DB
+	logger entities.Logger
+}
+
+// New opens (creating if needed) the SQLite database at the path given by
+// the storage.sqlite.path config key and auto-migrates the schema.
+func New(config entities.ConfigGetter, logger entities.Logger) (*Store, error) {
+	path := config.GetString("storage.sqlite.path")
+	if path == "" {
+		path = "db/twixter.sqlite"
+	}
+
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite db at %q: %w", path, err)
+	}
+
+	if err := db.AutoMigrate(&TwitterProfile{}); err != nil {
+		return nil, fmt.Errorf("failed to migrate sqlite schema: %w", err)
+	}
+
+	return &Store{db: db, logger: logger}, nil
+}
+
+func (s *Store) GetProfile(username string) (*entities.TwitterProfile, error) {
+	tp := &TwitterProfile{}
+	if err := s.db.Where(&TwitterProfile{Username: username}).First(tp).Error; err != nil {
+		s.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to get profile from sqlite")
+
+		return nil, fmt.Errorf("failed to fetch profile from sqlite: %w", err)
+	}
+
+	return s.asTwitterProfile(tp), nil
+}
+
+func (s *Store) SaveProfiles(profiles []*entities.TwitterProfile) error {
+	tps := make([]*TwitterProfile, 0, len(profiles))
+	for _, p := range profiles {
+		tps = append(tps, s.fromTwitterProfile(p))
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "twitter_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"name", "username", "location", "bio", "url", "email",
+			"profile_banner_url", "profile_image_url", "verified", "protected",
+			"default_profile", "default_profile_image", "followers_count",
+			"followings_count", "favourites_count", "listed_count", "tweets_count",
+			"entities", "joined_at",
+		}),
+	}).CreateInBatches(tps, saveProfilesBatchSize).Error
+	if err != nil {
+		s.logger.As("E").WithError(err).Logf("Failed to save profiles in sqlite")
+
+		return fmt.Errorf("failed to save profiles in sqlite: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) AddFollowings(profile *entities.TwitterProfile, profiles []*entities.TwitterProfile) error {
+	tp := s.fromTwitterProfile(profile)
+
+	for _, p := range profiles {
+		tp.Followings = append(tp.Followings, s.fromTwitterProfile(p))
+	}
+
+	if err := s.db.Session(&gorm.Session{FullSaveAssociations: true}).Updates(tp).Error; err != nil {
+		s.logger.As("E").WithError(err).WithField("username", profile.Username).Logf("Failed to add followings in sqlite")
+
+		return fmt.Errorf("failed to add followings in sqlite: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) AddFollowers(profile *entities.TwitterProfile, profiles []*entities.TwitterProfile) error {
+	tp := s.fromTwitterProfile(profile)
+
+	for _, p := range profiles {
+		tp.Followers = append(tp.Followers, s.fromTwitterProfile(p))
+	}
+
+	if err := s.db.Session(&gorm.Session{FullSaveAssociations: true}).Updates(tp).Error; err != nil {
+		s.logger.As("E").WithError(err).WithField("username", profile.Username).Logf("Failed to add followers in sqlite")
+
+		return fmt.Errorf("failed to add followers in sqlite: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) GetFollowings(username string) ([]*entities.TwitterProfile, error) {
+	profiles := []*TwitterProfile{}
+	if err := s.db.Model(&TwitterProfile{Username: username}).Association("Followings").Find(&profiles); err != nil {
+		s.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to get followings from sqlite")
+
+		return nil, fmt.Errorf("failed to get followings from sqlite: %w", err)
+	}
+
+	return s.asTwitterProfiles(profiles), nil
+}
+
+func (s *Store) GetFollowers(username string) ([]*entities.TwitterProfile, error) {
+	profiles := []*TwitterProfile{}
+	if err := s.db.Model(&TwitterProfile{Username: username}).Association("Followers").Find(&profiles); err != nil {
+		s.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to get followers from sqlite")
+
+		return nil, fmt.Errorf("failed to get followers from sqlite: %w", err)
+	}
+
+	return s.asTwitterProfiles(profiles), nil
+}
+
+func (s *Store) GetTopFollowingsByFollowers(username string, limit int) ([]*entities.TwitterProfile, error) {
+	profiles := []*TwitterProfile{}
+	if err := s.db.
+		Joins("JOIN sqlite_followings ON sqlite_followings.twitter_profile_following_id = twitter_profiles.id").
+		Joins("JOIN twitter_profiles AS owner ON owner.id = sqlite_followings.twitter_profile_id").
+		Where("owner.username = ?", username).
+		Order("twitter_profiles.followers_count DESC").
+		Limit(limit).
+		Find(&profiles).Error; err != nil {
+		s.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to get top followings by followers from sqlite")
+
+		return nil, fmt.Errorf("failed to get top followings by followers from sqlite: %w", err)
+	}
+
+	return s.asTwitterProfiles(profiles), nil
+}
+
+func (s *Store) GetTopFollowersByFollowers(username string, limit int) ([]*entities.TwitterProfile, error) {
+	profiles := []*TwitterProfile{}
+	if err := s.db.
+		Joins("JOIN sqlite_followers ON sqlite_followers.twitter_profile_follower_id = twitter_profiles.id").
+		Joins("JOIN twitter_profiles AS owner ON owner.id = sqlite_followers.twitter_profile_id").
+		Where("owner.username = ?", username).
+		Order("twitter_profiles.followers_count DESC").
+		Limit(limit).
+		Find(&profiles).Error; err != nil {
+		s.logger.As("E").WithError(err).WithField("username", username).Logf("Failed to get top followers by followers from sqlite")
+
+		return nil, fmt.Errorf("failed to get top followers by followers from sqlite: %w", err)
+	}
+
+	return s.asTwitterProfiles(profiles), nil
+}
+
+func (s *Store) fromTwitterProfile(profile *entities.TwitterProfile) *TwitterProfile {
+	entitiesJSON, err := json.Marshal(profile.Entities)
+	if err != nil {
+		s.logger.As("W").WithError(err).Logf("Failed to serialize profile.Entities")
+	}
+
+	return &TwitterProfile{
+		TwitterID:           profile.TwitterID,
+		Name:                profile.Name,
+		Username:            profile.Username,
+		Location:            profile.Location,
+		Bio:                 profile.Bio,
+		URL:                 profile.URL,
+		Email:               profile.Email,
+		ProfileBannerURL:    profile.ProfileBannerURL,
+		ProfileImageURL:     profile.ProfileImageURL,
+		Verified:            profile.Verified,
+		Protected:           profile.Protected,
+		DefaultProfile:      profile.DefaultProfile,
+		DefaultProfileImage: profile.DefaultProfileImage,
+		FollowersCount:      profile.FollowersCount,
+		FollowingsCount:     profile.FollowingsCount,
+		FavouritesCount:     profile.FavouritesCount,
+		ListedCount:         profile.ListedCount,
+		TweetsCount:         profile.TweetsCount,
+		Entities:            entitiesJSON,
+		JoinedAt:            profile.JoinedAt,
+	}
+}
+
+func (s *Store) asTwitterProfile(profile *TwitterProfile) *entities.TwitterProfile {
+	var ent map[string]interface{}
+
+	if err := json.Unmarshal(profile.Entities, &ent); err != nil {
+		s.logger.As("W").WithError(err).Logf("Failed to deserialize profile.Entities")
+	}
+
+	return &entities.TwitterProfile{
+		TwitterID:           profile.TwitterID,
+		Name:                profile.Name,
+		Username:            profile.Username,
+		Location:            profile.Location,
+		Bio:                 profile.Bio,
+		URL:                 profile.URL,
+		Email:               profile.Email,
+		ProfileBannerURL:    profile.ProfileBannerURL,
+		ProfileImageURL:     profile.ProfileImageURL,
+		Verified:            profile.Verified,
+		Protected:           profile.Protected,
+		DefaultProfile:      profile.DefaultProfile,
+		DefaultProfileImage: profile.DefaultProfileImage,
+		FollowersCount:      profile.FollowersCount,
+		FollowingsCount:     profile.FollowingsCount,
+		FavouritesCount:     profile.FavouritesCount,
+		ListedCount:         profile.ListedCount,
+		TweetsCount:         profile.TweetsCount,
+		Entities:            ent,
+		JoinedAt:            profile.JoinedAt,
+	}
+}
+
+func (s *Store) asTwitterProfiles(profiles []*TwitterProfile) []*entities.TwitterProfile {
+	results := make([]*entities.TwitterProfile, 0, len(profiles))
+	for _, p := range profiles {
+		results = append(results, s.asTwitterProfile(p))
+	}
+
+	return results
+}
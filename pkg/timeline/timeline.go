@@ -0,0 +1,204 @@
+// Package timeline turns successive Twitter snapshots into an ordered,
+// temporal record of what changed. It sits between the fetcher and a
+// TwitterStore: diff two snapshots into Events, buffer them per user, and
+// flush them into storage so callers can ask "who did X follow in the last
+// 7 days" instead of only ever seeing the latest state.
+package timeline
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mehdy/twixter/pkg/entities"
+	"github.com/oklog/ulid"
+)
+
+// EventType identifies the kind of change an Event records.
+type EventType string
+
+const (
+	FollowAdded     EventType = "follow_added"
+	FollowRemoved   EventType = "follow_removed"
+	ProfileUpdated  EventType = "profile_updated"
+	ringBufferSize            = 4096
+)
+
+// Event is a single timestamped change observed for a user. TargetUsername
+// is set for FollowAdded/FollowRemoved; Profile is set for ProfileUpdated.
+type Event struct {
+	ID             ulid.ULID
+	Type           EventType
+	Username       string
+	TargetUsername string
+	Profile        *entities.TwitterProfile
+	OccurredAt     time.Time
+}
+
+// Store persists buffered events. It is implemented by pkg/services/postgres.Twitter.
+type Store interface {
+	SaveFollowEvents(events []Event) error
+	SaveProfileRevisions(events []Event) error
+}
+
+// ringBuffer is a fixed-capacity FIFO of Events, oldest dropped first.
+type ringBuffer struct {
+	events []Event
+}
+
+func (r *ringBuffer) push(e Event) {
+	r.events = append(r.events, e)
+	if len(r.events) > ringBufferSize {
+		r.events = r.events[len(r.events)-ringBufferSize:]
+	}
+}
+
+// since returns the buffered events that occurred at or after cutoff, in
+// the order they were pushed.
+func (r *ringBuffer) since(cutoff time.Time) []Event {
+	events := make([]Event, 0, len(r.events))
+
+	for _, e := range r.events {
+		if !e.OccurredAt.Before(cutoff) {
+			events = append(events, e)
+		}
+	}
+
+	return events
+}
+
+// TimelineManager buffers per-user Events in memory and flushes them to a
+// Store. Event IDs are generated with monotonic ULIDs so events ingested
+// within the same second still sort in arrival order.
+type TimelineManager struct {
+	mu      sync.Mutex
+	buffers map[string]*ringBuffer
+	entropy io.Reader
+	store   Store
+}
+
+// NewTimelineManager builds a TimelineManager that flushes into store.
+func NewTimelineManager(store Store) *TimelineManager {
+	return &TimelineManager{
+		buffers: map[string]*ringBuffer{},
+		entropy: ulid.Monotonic(rand.Reader, 0),
+		store:   store,
+	}
+}
+
+// Ingest reads events for profile.Username off events until the channel is
+// closed or ctx is done, buffering each in the user's ring buffer and
+// flushing it to the Store. It returns the first flush error encountered,
+// after draining the remainder of the channel so the caller's producer
+// doesn't block on a full channel.
+func (m *TimelineManager) Ingest(ctx context.Context, profile *entities.TwitterProfile, events <-chan Event) error {
+	var flushErr error
+
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return flushErr
+			}
+
+			if flushErr == nil {
+				if err := m.ingestOne(profile.Username, e); err != nil {
+					flushErr = fmt.Errorf("failed to ingest event for %q: %w", profile.Username, err)
+				}
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *TimelineManager) ingestOne(username string, e Event) error {
+	if e.ID.Compare(ulid.ULID{}) == 0 {
+		e.ID = m.nextID(e.OccurredAt)
+	}
+
+	m.mu.Lock()
+	buf, ok := m.buffers[username]
+	if !ok {
+		buf = &ringBuffer{}
+		m.buffers[username] = buf
+	}
+	buf.push(e)
+	m.mu.Unlock()
+
+	switch e.Type {
+	case FollowAdded, FollowRemoved:
+		return m.store.SaveFollowEvents([]Event{e})
+	case ProfileUpdated:
+		return m.store.SaveProfileRevisions([]Event{e})
+	default:
+		return fmt.Errorf("unknown event type %q", e.Type)
+	}
+}
+
+// Recent returns username's buffered events that occurred within the last
+// window, newest-last, without touching the Store. It only sees events
+// still held in the in-memory ring buffer, so callers needing history
+// older than ringBufferSize events should query the Store instead.
+func (m *TimelineManager) Recent(username string, window time.Duration) []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	buf, ok := m.buffers[username]
+	if !ok {
+		return nil
+	}
+
+	return buf.since(time.Now().Add(-window))
+}
+
+func (m *TimelineManager) nextID(at time.Time) ulid.ULID {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return ulid.MustNew(ulid.Timestamp(at), m.entropy)
+}
+
+// DiffFollowings compares two successive GetFollowings snapshots for
+// username and returns the FollowAdded/FollowRemoved events between them.
+func DiffFollowings(username string, before, after []*entities.TwitterProfile) []Event {
+	return diffFollows(username, before, after)
+}
+
+// DiffFollowers compares two successive GetFollowers snapshots for username
+// and returns the FollowAdded/FollowRemoved events between them.
+func DiffFollowers(username string, before, after []*entities.TwitterProfile) []Event {
+	return diffFollows(username, before, after)
+}
+
+func diffFollows(username string, before, after []*entities.TwitterProfile) []Event {
+	prev := make(map[string]bool, len(before))
+	for _, p := range before {
+		prev[p.Username] = true
+	}
+
+	curr := make(map[string]bool, len(after))
+	for _, p := range after {
+		curr[p.Username] = true
+	}
+
+	now := time.Now()
+	events := []Event{}
+
+	for u := range curr {
+		if !prev[u] {
+			events = append(events, Event{Type: FollowAdded, Username: username, TargetUsername: u, OccurredAt: now})
+		}
+	}
+
+	for u := range prev {
+		if !curr[u] {
+			events = append(events, Event{Type: FollowRemoved, Username: username, TargetUsername: u, OccurredAt: now})
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,97 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mehdy/twixter/pkg/entities"
+)
+
+func TestDiffFollowingsAddedAndRemoved(t *testing.T) {
+	before := []*entities.TwitterProfile{{Username: "bob"}, {Username: "carol"}}
+	after := []*entities.TwitterProfile{{Username: "carol"}, {Username: "dave"}}
+
+	events := DiffFollowings("alice", before, after)
+
+	var added, removed string
+
+	for _, e := range events {
+		switch e.Type {
+		case FollowAdded:
+			added = e.TargetUsername
+		case FollowRemoved:
+			removed = e.TargetUsername
+		default:
+			t.Fatalf("unexpected event type %q", e.Type)
+		}
+
+		if e.Username != "alice" {
+			t.Fatalf("Event.Username = %q, want alice", e.Username)
+		}
+	}
+
+	if added != "dave" {
+		t.Fatalf("FollowAdded target = %q, want dave", added)
+	}
+
+	if removed != "bob" {
+		t.Fatalf("FollowRemoved target = %q, want bob", removed)
+	}
+}
+
+func TestDiffFollowingsNoChange(t *testing.T) {
+	snapshot := []*entities.TwitterProfile{{Username: "bob"}}
+
+	if events := DiffFollowings("alice", snapshot, snapshot); len(events) != 0 {
+		t.Fatalf("DiffFollowings() = %v, want no events for an unchanged snapshot", events)
+	}
+}
+
+func TestTimelineManagerRecentFiltersByWindow(t *testing.T) {
+	m := NewTimelineManager(nopStore{})
+
+	old := Event{Type: FollowAdded, Username: "alice", TargetUsername: "bob", OccurredAt: time.Now().Add(-time.Hour)}
+	if err := m.ingestOne("alice", old); err != nil {
+		t.Fatalf("ingestOne() error = %v", err)
+	}
+
+	fresh := Event{Type: FollowAdded, Username: "alice", TargetUsername: "carol", OccurredAt: time.Now()}
+	if err := m.ingestOne("alice", fresh); err != nil {
+		t.Fatalf("ingestOne() error = %v", err)
+	}
+
+	recent := m.Recent("alice", time.Minute)
+	if len(recent) != 1 || recent[0].TargetUsername != "carol" {
+		t.Fatalf("Recent() = %+v, want only the carol event", recent)
+	}
+}
+
+func TestTimelineManagerRecentUnknownUsername(t *testing.T) {
+	m := NewTimelineManager(nopStore{})
+
+	if recent := m.Recent("nobody", time.Hour); recent != nil {
+		t.Fatalf("Recent() = %+v, want nil for an unseen username", recent)
+	}
+}
+
+type nopStore struct{}
+
+func (nopStore) SaveFollowEvents(events []Event) error     { return nil }
+func (nopStore) SaveProfileRevisions(events []Event) error { return nil }
+
+func TestTimelineManagerNextIDMonotonic(t *testing.T) {
+	m := NewTimelineManager(nil)
+
+	at := time.Unix(1700000000, 0)
+
+	first := m.nextID(at)
+	second := m.nextID(at)
+
+	if first.Compare(second) != -1 {
+		t.Fatalf("nextID() not monotonic for same-second timestamps: first=%s second=%s", first, second)
+	}
+
+	if first.Time() != second.Time() {
+		t.Fatalf("nextID() timestamps differ for same input time: first=%s second=%s", first, second)
+	}
+}
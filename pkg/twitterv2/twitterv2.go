@@ -0,0 +1,396 @@
+// Package twitterv2 talks directly to the Twitter API v2 (api.twitter.com/2)
+// using a PKCE OAuth2 Authorization-Code flow for user-context tokens. It is
+// a drop-in replacement for the v1.1-based root Twitter client for callers
+// who need user-context data (e.g. Email) or who are affected by the v1.1
+// sunset.
+package twitterv2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+const (
+	apiBaseURL   = "https://api.twitter.com/2"
+	authURL      = "https://twitter.com/i/oauth2/authorize"
+	tokenURL     = "https://api.twitter.com/2/oauth2/token"
+	maxPageCount = 1000
+)
+
+// TokenStore persists and retrieves the OAuth2 refresh token for a user so a
+// crawl can resume across process restarts without repeating the PKCE
+// authorization step.
+type TokenStore interface {
+	SaveToken(username string, token *oauth2.Token) error
+	LoadToken(username string) (*oauth2.Token, error)
+}
+
+// TwitterProfile represents a user's profile as returned by the v2 /users
+// endpoints, extended with fields that have no v1.1 equivalent.
+type TwitterProfile struct {
+	TwitterID       string
+	Name            string
+	Username        string
+	Bio             string
+	URL             string
+	ProfileImageURL string
+	Verified        bool
+	Protected       bool
+	CreatedAt       time.Time
+	PinnedTweetID   string
+	Withheld        []string
+	PublicMetrics   PublicMetrics
+}
+
+// PublicMetrics mirrors the `public_metrics` object on a v2 user.
+type PublicMetrics struct {
+	FollowersCount int
+	FollowingCount int
+	TweetCount     int
+	ListedCount    int
+}
+
+// RateLimitError is returned when a v2 endpoint reports it is exhausted.
+// Reset is the time at which the limit is expected to replenish, parsed
+// from the `x-rate-limit-reset` response header.
+type RateLimitError struct {
+	Endpoint string
+	Reset    time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exceeded for %q, resets at %s", e.Endpoint, e.Reset.Format(time.RFC3339))
+}
+
+// Twitter is a v2, user-context Twitter API client.
+type Twitter struct {
+	http       *http.Client
+	oauthConf  *oauth2.Config
+	tokenStore TokenStore
+	log        *logrus.Logger
+
+	// waitOnRateLimit controls whether a rate-limited request sleeps until
+	// reset (true) or returns a *RateLimitError immediately (false).
+	waitOnRateLimit bool
+
+	rateLimitMu sync.Mutex
+	// rateLimits caches the last x-rate-limit-remaining/x-rate-limit-reset
+	// seen per endpoint, so a request that would exhaust the limit is
+	// throttled before it's even sent instead of only after a 429.
+	rateLimits map[string]rateLimitState
+}
+
+type rateLimitState struct {
+	remaining int
+	reset     time.Time
+}
+
+// NewTwitter builds a v2 client configured for the PKCE Authorization-Code
+// flow. The returned client has no token until Authorize (or a prior token
+// loaded from tokenStore) is used to obtain one.
+func NewTwitter(config *viper.Viper, log *logrus.Logger, tokenStore TokenStore) *Twitter {
+	return &Twitter{
+		http: http.DefaultClient,
+		oauthConf: &oauth2.Config{
+			ClientID:    config.GetString("twitter.v2.client_id"),
+			RedirectURL: config.GetString("twitter.v2.redirect_url"),
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  authURL,
+				TokenURL: tokenURL,
+			},
+			Scopes: []string{"tweet.read", "users.read", "follows.read", "offline.access"},
+		},
+		tokenStore:      tokenStore,
+		log:             log,
+		waitOnRateLimit: config.GetBool("twitter.v2.wait_on_rate_limit"),
+		rateLimits:      map[string]rateLimitState{},
+	}
+}
+
+// AuthCodeURL returns the PKCE authorization URL the user must visit, along
+// with the code verifier that must be passed back into Exchange.
+func (t *Twitter) AuthCodeURL(state string) (url, verifier string) {
+	verifier = oauth2.GenerateVerifier()
+
+	return t.oauthConf.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier)), verifier
+}
+
+// Exchange trades the authorization code for a token, persists it via the
+// configured TokenStore, and returns an authenticated context.Context client
+// for username.
+func (t *Twitter) Exchange(ctx context.Context, username, code, verifier string) (*http.Client, error) {
+	token, err := t.oauthConf.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange PKCE authorization code: %w", err)
+	}
+
+	if err := t.tokenStore.SaveToken(username, token); err != nil {
+		return nil, fmt.Errorf("failed to persist token for %q: %w", username, err)
+	}
+
+	return t.oauthConf.Client(ctx, token), nil
+}
+
+// clientFor returns an http.Client that attaches and auto-refreshes the
+// stored token for username. Twitter rotates refresh tokens on every use,
+// so every silent refresh is written back to tokenStore; otherwise a
+// resumed crawl would present an already-rotated, invalid refresh token.
+func (t *Twitter) clientFor(ctx context.Context, username string) (*http.Client, error) {
+	token, err := t.tokenStore.LoadToken(username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token for %q: %w", username, err)
+	}
+
+	source := oauth2.ReuseTokenSource(token, &persistingTokenSource{
+		username: username,
+		base:     t.oauthConf.TokenSource(ctx, token),
+		store:    t.tokenStore,
+	})
+
+	return oauth2.NewClient(ctx, source), nil
+}
+
+// persistingTokenSource wraps an oauth2.TokenSource so that every token it
+// hands back (i.e. every time the underlying source actually refreshes) is
+// persisted to store before being returned.
+type persistingTokenSource struct {
+	username string
+	base     oauth2.TokenSource
+	store    TokenStore
+}
+
+func (p *persistingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := p.base.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token for %q: %w", p.username, err)
+	}
+
+	if err := p.store.SaveToken(p.username, token); err != nil {
+		return nil, fmt.Errorf("failed to persist refreshed token for %q: %w", p.username, err)
+	}
+
+	return token, nil
+}
+
+// GetProfile fetches the v2 profile of username using username's own stored
+// user-context token.
+func (t *Twitter) GetProfile(ctx context.Context, username string) (*TwitterProfile, error) {
+	client, err := t.clientFor(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("user.fields", "created_at,protected,profile_image_url,public_metrics,verified,pinned_tweet_id,withheld,description,url")
+
+	reqURL := fmt.Sprintf("%s/users/by/username/%s?%s", apiBaseURL, url.PathEscape(username), query.Encode())
+
+	var body struct {
+		Data userResource `json:"data"`
+	}
+
+	if err := t.getJSON(ctx, client, "GetProfile", reqURL, &body); err != nil {
+		return nil, err
+	}
+
+	return body.Data.toTwitterProfile(), nil
+}
+
+// GetFollowings resumes (or starts, if cursor is "") a paginated walk of
+// username's followings, returning at most maxPageCount profiles per call
+// along with the pagination_token to resume from on the next call. An empty
+// nextCursor means the walk is complete.
+func (t *Twitter) GetFollowings(ctx context.Context, username, cursor string) (profiles []*TwitterProfile, nextCursor string, err error) {
+	return t.paginateConnections(ctx, username, "following", cursor)
+}
+
+// GetFollowers is the GetFollowings counterpart for username's followers.
+func (t *Twitter) GetFollowers(ctx context.Context, username, cursor string) (profiles []*TwitterProfile, nextCursor string, err error) {
+	return t.paginateConnections(ctx, username, "followers", cursor)
+}
+
+func (t *Twitter) paginateConnections(ctx context.Context, username, relation, cursor string) ([]*TwitterProfile, string, error) {
+	client, err := t.clientFor(ctx, username)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := url.Values{}
+	query.Set("max_results", strconv.Itoa(maxPageCount))
+	query.Set("user.fields", "created_at,protected,profile_image_url,public_metrics,verified,pinned_tweet_id,withheld")
+
+	if cursor != "" {
+		query.Set("pagination_token", cursor)
+	}
+
+	reqURL := fmt.Sprintf("%s/users/by/username/%s/%s?%s", apiBaseURL, url.PathEscape(username), relation, query.Encode())
+
+	var body struct {
+		Data []userResource `json:"data"`
+		Meta struct {
+			NextToken string `json:"next_token"`
+		} `json:"meta"`
+	}
+
+	if err := t.getJSON(ctx, client, "Get"+relation, reqURL, &body); err != nil {
+		return nil, "", err
+	}
+
+	profiles := make([]*TwitterProfile, 0, len(body.Data))
+	for _, u := range body.Data {
+		profiles = append(profiles, u.toTwitterProfile())
+	}
+
+	return profiles, body.Meta.NextToken, nil
+}
+
+func (t *Twitter) getJSON(ctx context.Context, client *http.Client, endpoint, url string, out interface{}) error {
+	if rlErr := t.throttle(endpoint); rlErr != nil {
+		if !t.waitOnRateLimit {
+			return rlErr
+		}
+
+		t.log.WithField("endpoint", endpoint).WithField("resetAt", rlErr.Reset).
+			Warn("twitterv2: remaining quota exhausted, sleeping until reset")
+		time.Sleep(time.Until(rlErr.Reset))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %q: %w", endpoint, err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %q: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	t.recordRateLimit(endpoint, resp.Header)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		rlErr := &RateLimitError{Endpoint: endpoint, Reset: parseRateLimitReset(resp.Header)}
+		if !t.waitOnRateLimit {
+			return rlErr
+		}
+
+		t.log.WithField("endpoint", endpoint).WithField("resetAt", rlErr.Reset).Warn("twitterv2: rate limited, sleeping until reset")
+		time.Sleep(time.Until(rlErr.Reset))
+
+		return t.getJSON(ctx, client, endpoint, url, out)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%q returned unexpected status %d", endpoint, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response from %q: %w", endpoint, err)
+	}
+
+	return nil
+}
+
+// throttle returns a *RateLimitError if the last response seen for endpoint
+// reported its quota as exhausted and the reset time hasn't passed yet, so
+// callers can self-throttle proactively instead of always waiting for a 429.
+func (t *Twitter) throttle(endpoint string) *RateLimitError {
+	t.rateLimitMu.Lock()
+	state, ok := t.rateLimits[endpoint]
+	t.rateLimitMu.Unlock()
+
+	if !ok || state.remaining > 0 || time.Now().After(state.reset) {
+		return nil
+	}
+
+	return &RateLimitError{Endpoint: endpoint, Reset: state.reset}
+}
+
+// recordRateLimit caches the x-rate-limit-remaining/x-rate-limit-reset
+// headers of the most recent response for endpoint.
+func (t *Twitter) recordRateLimit(endpoint string, header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("x-rate-limit-remaining"))
+	if err != nil {
+		return
+	}
+
+	t.rateLimitMu.Lock()
+	defer t.rateLimitMu.Unlock()
+
+	t.rateLimits[endpoint] = rateLimitState{
+		remaining: remaining,
+		reset:     parseRateLimitReset(header),
+	}
+}
+
+// parseRateLimitReset parses the `x-rate-limit-reset` header, which is a
+// unix timestamp. A missing or malformed header falls back to a 15 minute
+// wait, matching Twitter's documented default window.
+func parseRateLimitReset(header http.Header) time.Time {
+	raw := header.Get("x-rate-limit-reset")
+	if raw == "" {
+		return time.Now().Add(15 * time.Minute)
+	}
+
+	unix, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Now().Add(15 * time.Minute)
+	}
+
+	return time.Unix(unix, 0)
+}
+
+// userResource is the wire shape of a v2 `user` resource.
+type userResource struct {
+	ID              string    `json:"id"`
+	Name            string    `json:"name"`
+	Username        string    `json:"username"`
+	Description     string    `json:"description"`
+	URL             string    `json:"url"`
+	ProfileImageURL string    `json:"profile_image_url"`
+	Verified        bool      `json:"verified"`
+	Protected       bool      `json:"protected"`
+	CreatedAt       time.Time `json:"created_at"`
+	PinnedTweetID   string    `json:"pinned_tweet_id"`
+	Withheld        struct {
+		CountryCodes []string `json:"country_codes"`
+	} `json:"withheld"`
+	PublicMetrics struct {
+		FollowersCount int `json:"followers_count"`
+		FollowingCount int `json:"following_count"`
+		TweetCount     int `json:"tweet_count"`
+		ListedCount    int `json:"listed_count"`
+	} `json:"public_metrics"`
+}
+
+func (u userResource) toTwitterProfile() *TwitterProfile {
+	return &TwitterProfile{
+		TwitterID:       u.ID,
+		Name:            u.Name,
+		Username:        u.Username,
+		Bio:             u.Description,
+		URL:             u.URL,
+		ProfileImageURL: u.ProfileImageURL,
+		Verified:        u.Verified,
+		Protected:       u.Protected,
+		CreatedAt:       u.CreatedAt,
+		PinnedTweetID:   u.PinnedTweetID,
+		Withheld:        u.Withheld.CountryCodes,
+		PublicMetrics: PublicMetrics{
+			FollowersCount: u.PublicMetrics.FollowersCount,
+			FollowingCount: u.PublicMetrics.FollowingCount,
+			TweetCount:     u.PublicMetrics.TweetCount,
+			ListedCount:    u.PublicMetrics.ListedCount,
+		},
+	}
+}
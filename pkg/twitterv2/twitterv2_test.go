@@ -0,0 +1,128 @@
+package twitterv2
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+type fakeTokenSource struct {
+	token *oauth2.Token
+	err   error
+}
+
+func (f *fakeTokenSource) Token() (*oauth2.Token, error) {
+	return f.token, f.err
+}
+
+type fakeTokenStore struct {
+	saved map[string]*oauth2.Token
+	err   error
+}
+
+func (f *fakeTokenStore) SaveToken(username string, token *oauth2.Token) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	if f.saved == nil {
+		f.saved = map[string]*oauth2.Token{}
+	}
+
+	f.saved[username] = token
+
+	return nil
+}
+
+func (f *fakeTokenStore) LoadToken(username string) (*oauth2.Token, error) {
+	return f.saved[username], nil
+}
+
+func TestPersistingTokenSourcePersistsRefreshedToken(t *testing.T) {
+	store := &fakeTokenStore{}
+	refreshed := &oauth2.Token{AccessToken: "new-access-token", RefreshToken: "new-refresh-token"}
+	src := &persistingTokenSource{username: "alice", base: &fakeTokenSource{token: refreshed}, store: store}
+
+	got, err := src.Token()
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	if got != refreshed {
+		t.Fatalf("Token() = %v, want the refreshed token", got)
+	}
+
+	if store.saved["alice"] != refreshed {
+		t.Fatalf("SaveToken was not called with the refreshed token for alice")
+	}
+}
+
+func TestPersistingTokenSourceBaseErrorNotPersisted(t *testing.T) {
+	store := &fakeTokenStore{}
+	src := &persistingTokenSource{username: "alice", base: &fakeTokenSource{err: errors.New("refresh failed")}, store: store}
+
+	if _, err := src.Token(); err == nil {
+		t.Fatal("Token() error = nil, want the base source's error wrapped")
+	}
+
+	if len(store.saved) != 0 {
+		t.Fatalf("SaveToken was called despite a base refresh error: %v", store.saved)
+	}
+}
+
+func TestPersistingTokenSourceSaveErrorSurfaces(t *testing.T) {
+	store := &fakeTokenStore{err: errors.New("disk full")}
+	src := &persistingTokenSource{username: "alice", base: &fakeTokenSource{token: &oauth2.Token{}}, store: store}
+
+	if _, err := src.Token(); err == nil {
+		t.Fatal("Token() error = nil, want the store's error wrapped")
+	}
+}
+
+func TestThrottleAllowsUntilExhausted(t *testing.T) {
+	tw := &Twitter{rateLimits: map[string]rateLimitState{}}
+
+	if err := tw.throttle("GetProfile"); err != nil {
+		t.Fatalf("throttle() = %v, want nil for an endpoint never seen before", err)
+	}
+
+	tw.recordRateLimit("GetProfile", http.Header{
+		"X-Rate-Limit-Remaining": []string{"0"},
+		"X-Rate-Limit-Reset":     []string{timeUnix(time.Now().Add(time.Hour))},
+	})
+
+	if err := tw.throttle("GetProfile"); err == nil {
+		t.Fatal("throttle() = nil, want a RateLimitError once remaining hits 0 before reset")
+	}
+}
+
+func TestThrottleAllowsAfterReset(t *testing.T) {
+	tw := &Twitter{rateLimits: map[string]rateLimitState{}}
+
+	tw.recordRateLimit("GetProfile", http.Header{
+		"X-Rate-Limit-Remaining": []string{"0"},
+		"X-Rate-Limit-Reset":     []string{timeUnix(time.Now().Add(-time.Hour))},
+	})
+
+	if err := tw.throttle("GetProfile"); err != nil {
+		t.Fatalf("throttle() = %v, want nil once the reset time has passed", err)
+	}
+}
+
+func TestRecordRateLimitIgnoresMissingHeader(t *testing.T) {
+	tw := &Twitter{rateLimits: map[string]rateLimitState{}}
+
+	tw.recordRateLimit("GetProfile", http.Header{})
+
+	if _, ok := tw.rateLimits["GetProfile"]; ok {
+		t.Fatal("recordRateLimit cached a state despite a missing x-rate-limit-remaining header")
+	}
+}
+
+func timeUnix(at time.Time) string {
+	return strconv.FormatInt(at.Unix(), 10)
+}
@@ -48,6 +48,15 @@ type TwitterProfile struct {
 	FollowersIDs        []string // TwitterID references
 }
 
+// Fetcher is implemented by anything that can retrieve Twitter profiles and
+// their social graph, whether via the official API (Twitter) or a scraper
+// fallback (pkg/scraper) for users without API access.
+type Fetcher interface {
+	GetProfile(username string) (*TwitterProfile, error)
+	GetFollowings(username string) []*TwitterProfile
+	GetFollowers(username string) []*TwitterProfile
+}
+
 type Twitter struct {
 	api *twitter.Client
 	log *logrus.Logger